@@ -0,0 +1,324 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// EncodeOptions control the optional parts of Frames.Encode and
+// WriteFile. The zero value writes the frames with no padding, no
+// footer, and no unsynchronisation beyond whatever individual
+// frames already request via their Flags.
+type EncodeOptions struct {
+	// Unsynchronisation requests that the tag-level
+	// unsynchronisation scheme described in §5 of
+	// id3v2.4.0-structure.txt be applied to the whole tag. This is
+	// the only way to unsynchronise a v2.3.0 tag; v2.4.0 frames
+	// are unsynchronised individually, based on their own Flags.
+	Unsynchronisation bool
+
+	// Padding is the number of zero bytes written after the last
+	// frame. It is ignored when Footer is set.
+	Padding int
+
+	// Footer requests that a v2.4.0 footer, identical to the
+	// header, be written after the frames instead of padding.
+	// Footer is only valid for Version24.
+	Footer bool
+}
+
+// Encode serializes the frames as a single ID3v2 tag block of the
+// given version and writes it to w.
+func (f Frames) Encode(w io.Writer, version Version, opts ...EncodeOptions) error {
+	var opt EncodeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	switch version {
+	case Version23, Version24:
+	default:
+		return fmt.Errorf("id3: unsupported version %#x", byte(version))
+	}
+
+	if opt.Footer && version != Version24 {
+		return errors.New("id3: footer is only valid for Version24")
+	}
+	if opt.Padding < 0 {
+		return errors.New("id3: padding must not be negative")
+	}
+
+	var body bytes.Buffer
+	for _, frame := range f {
+		if err := frame.encode(&body, version); err != nil {
+			return err
+		}
+	}
+
+	data := body.Bytes()
+
+	var flags byte
+	if opt.Unsynchronisation {
+		data = unsynchronise(data)
+		flags |= byte(TagFlagUnsynchronisation)
+	}
+	if opt.Footer {
+		flags |= byte(TagFlagFooter)
+	}
+
+	padding := opt.Padding
+	if opt.Footer {
+		padding = 0
+	}
+
+	size := uint32(len(data) + padding)
+	if size >= 1<<28 {
+		return errors.New("id3: tag is too large to encode")
+	}
+
+	var header [10]byte
+	copy(header[:3], id3Token)
+	header[3] = byte(version)
+	header[5] = flags
+	putSyncsafe(header[6:10], size)
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if opt.Footer {
+		header[0], header[1], header[2] = '3', 'D', 'I'
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	_, err := io.CopyN(w, zeroReader{}, int64(opt.Padding))
+	return err
+}
+
+// WriteFile encodes the frames as a single ID3v2 tag block of the
+// given version and writes it to the file at path. If path already
+// exists, only its leading ID3v2 tag, if it has one, is replaced;
+// everything after it, the audio payload and any trailing ID3v1
+// tag, is preserved. If path does not exist, it is created with
+// just the tag.
+func WriteFile(path string, frames Frames, version Version, opts ...EncodeOptions) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return frames.Encode(f, version, opts...)
+	}
+	defer src.Close()
+
+	skip, err := leadingTagSize(src)
+	if err != nil {
+		return err
+	}
+	if _, err := src.Seek(skip, io.SeekStart); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := frames.Encode(tmp, version, opts...); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// leadingTagSize reports the length, in bytes, of the ID3v2 tag
+// block at the very start of r, including its footer if it has
+// one, or 0 if r does not begin with one.
+func leadingTagSize(r io.Reader) (int64, error) {
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if string(header[:3]) != "ID3" {
+		return 0, nil
+	}
+
+	switch Version(header[3]) {
+	case Version23, Version24:
+	default:
+		return 0, nil
+	}
+
+	if header[4] == 0xff {
+		return 0, nil
+	}
+
+	flags := TagFlags(header[5])
+	if flags&^knownTagFlags != 0 {
+		return 0, nil
+	}
+
+	size := syncsafe(header[6:10])
+	if size == syncsafeInvalid {
+		return 0, nil
+	}
+
+	total := int64(10) + int64(size)
+	if flags&TagFlagFooter != 0 {
+		total += 10
+	}
+
+	return total, nil
+}
+
+// zeroReader is an io.Reader that reads an unbounded stream of
+// zero bytes, used to write padding via io.CopyN.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// encode writes the frame's header and body, honoring
+// FrameFlagV24Unsynchronisation when set on a v2.4.0 frame.
+func (f *Frame) encode(w io.Writer, version Version) error {
+	mask := FrameFlags(encodingFrameFlags)
+	if version == Version24 {
+		mask &^= FrameFlagV24Unsynchronisation
+	}
+	if f.Flags&mask != 0 {
+		return fmt.Errorf("id3: frame %#08x uses unsupported flags", uint32(f.ID))
+	}
+
+	data := f.Data
+	if version == Version24 && f.Flags&FrameFlagV24Unsynchronisation != 0 {
+		data = unsynchronise(data)
+	}
+
+	if len(data) >= 1<<28 {
+		return fmt.Errorf("id3: frame %#08x is too large to encode", uint32(f.ID))
+	}
+
+	var header [10]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(f.ID))
+
+	switch version {
+	case Version24:
+		putSyncsafe(header[4:8], uint32(len(data)))
+	case Version23:
+		binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
+	}
+
+	binary.BigEndian.PutUint16(header[8:10], uint16(f.Flags))
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+// putSyncsafe is the inverse of syncsafe: it encodes v into the
+// four bytes of dst as a syncsafe integer.
+func putSyncsafe(dst []byte, v uint32) {
+	_ = dst[3]
+
+	dst[0] = byte(v>>21) & 0x7f
+	dst[1] = byte(v>>14) & 0x7f
+	dst[2] = byte(v>>7) & 0x7f
+	dst[3] = byte(v) & 0x7f
+}
+
+// unsynchronise returns a copy of data with a zero byte inserted
+// after every occurrence of 0xff, the inverse of the de-stuffing
+// Scan performs when a frame or tag is flagged as unsynchronised.
+func unsynchronise(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for _, b := range data {
+		out = append(out, b)
+		if b == 0xff {
+			out = append(out, 0x00)
+		}
+	}
+	return out
+}
+
+// NewTextFrame builds a Frame containing a single text string
+// encoded per §4 of id3v2.4.0-structure.txt, suitable for any of
+// the "T???" text information frames. enc must be one of
+// textEncodingISO88591, textEncodingUTF16, textEncodingUTF16BE, or
+// textEncodingUTF8.
+func NewTextFrame(id FrameID, text string, enc byte) (*Frame, error) {
+	var data []byte
+	switch enc {
+	case textEncodingISO88591:
+		encoded, err := charmap.ISO8859_1.NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("id3: text is not representable in ISO-8859-1: %w", err)
+		}
+		data = append([]byte{enc}, encoded...)
+		data = append(data, zeroByte...)
+	case textEncodingUTF16:
+		encoded, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("id3: text is not representable in UTF-16: %w", err)
+		}
+		data = append([]byte{enc}, encoded...)
+		data = append(data, zeroBytes...)
+	case textEncodingUTF16BE:
+		encoded, err := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("id3: text is not representable in UTF-16BE: %w", err)
+		}
+		data = append([]byte{enc}, encoded...)
+		data = append(data, zeroBytes...)
+	case textEncodingUTF8:
+		data = append([]byte{enc}, []byte(text)...)
+		data = append(data, zeroByte...)
+	default:
+		return nil, fmt.Errorf("id3: unsupported text encoding %#x", enc)
+	}
+
+	return &Frame{ID: id, Data: data}, nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestFramesEncodeRejectsNegativePadding covers a negative
+// EncodeOptions.Padding, which would otherwise make the written tag
+// shorter than its declared size: io.CopyN writes nothing for a
+// negative count, but the syncsafe size field is computed as if the
+// padding bytes were written.
+func TestFramesEncodeRejectsNegativePadding(t *testing.T) {
+	frames := Frames{{ID: FramePRIV, Data: []byte("x")}}
+
+	var buf bytes.Buffer
+	err := frames.Encode(&buf, Version23, EncodeOptions{Padding: -1})
+	if err == nil {
+		t.Fatal("Encode: got nil error, want an error for negative padding")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// putSyncsafe5 is the inverse of syncsafe5: it encodes v into the
+// five bytes of dst as a syncsafe integer.
+func putSyncsafe5(dst []byte, v uint32) {
+	_ = dst[4]
+
+	dst[0] = byte(v>>28) & 0x7f
+	dst[1] = byte(v>>21) & 0x7f
+	dst[2] = byte(v>>14) & 0x7f
+	dst[3] = byte(v>>7) & 0x7f
+	dst[4] = byte(v) & 0x7f
+}
+
+// buildTagWithCRC builds a tag of the given version with an
+// extended header carrying a CRC-32 of one TIT2 frame, followed by
+// padding zero bytes. The CRC covers the padding for Version24, per
+// §3.2 of id3v2.4.0-structure.txt, and excludes it for Version23.
+func buildTagWithCRC(version Version, padding int) []byte {
+	frameData := []byte("hello")
+	frameHeader := []byte{'T', 'I', 'T', '2', 0, 0, 0, byte(len(frameData)), 0, 0}
+	frames := append(append([]byte(nil), frameHeader...), frameData...)
+
+	crcRange := append([]byte(nil), frames...)
+	if version == Version24 {
+		crcRange = append(crcRange, make([]byte, padding)...)
+	}
+	crc := crc32.ChecksumIEEE(crcRange)
+
+	var extBody []byte
+	switch version {
+	case Version24:
+		var crc5 [5]byte
+		putSyncsafe5(crc5[:], crc)
+		extBody = append([]byte{1, 0x20, 5}, crc5[:]...)
+	case Version23:
+		var eh [10]byte
+		eh[0] = 0x80
+		binary.BigEndian.PutUint32(eh[2:6], uint32(padding))
+		binary.BigEndian.PutUint32(eh[6:10], crc)
+		extBody = eh[:]
+	}
+
+	var extSize [4]byte
+	switch version {
+	case Version24:
+		putSyncsafe(extSize[:], uint32(len(extBody)))
+	case Version23:
+		binary.BigEndian.PutUint32(extSize[:], uint32(len(extBody)))
+	}
+
+	body := append(append([]byte(nil), extSize[:]...), extBody...)
+	body = append(body, frames...)
+	body = append(body, make([]byte, padding)...)
+
+	var header [10]byte
+	copy(header[:3], "ID3")
+	header[3] = byte(version)
+	header[5] = byte(TagFlagExtendedHeader)
+	putSyncsafe(header[6:10], uint32(len(body)))
+
+	return append(append([]byte(nil), header[:]...), body...)
+}
+
+// TestExtendedHeaderCRCIncludesPaddingV24 covers a v2.4.0 tag whose
+// extended-header CRC-32 was computed over the frames and the
+// padding that follows them, as the spec requires; a conformant
+// tagger that pads a CRC-checked tag must not be rejected.
+func TestExtendedHeaderCRCIncludesPaddingV24(t *testing.T) {
+	data := buildTagWithCRC(Version24, 8)
+
+	tags, err := ScanTags(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ScanTags: %v", err)
+	}
+	if len(tags) != 1 {
+		t.Fatalf("got %d tags, want 1", len(tags))
+	}
+}
+
+// TestExtendedHeaderCRCExcludesPaddingV23 covers a v2.3.0 tag whose
+// extended-header CRC-32 was computed over the frames alone,
+// excluding padding.
+func TestExtendedHeaderCRCExcludesPaddingV23(t *testing.T) {
+	data := buildTagWithCRC(Version23, 8)
+
+	tags, err := ScanTags(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ScanTags: %v", err)
+	}
+	if len(tags) != 1 {
+		t.Fatalf("got %d tags, want 1", len(tags))
+	}
+}
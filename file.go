@@ -0,0 +1,127 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ID3v1 is a trailing 128-byte ID3v1 (or ID3v1.1) tag, as commonly
+// appended to the last 128 bytes of an MP3 file.
+type ID3v1 struct {
+	Title   string
+	Artist  string
+	Album   string
+	Year    string
+	Comment string
+
+	// Genre is the raw ID3v1 genre index; it is not validated
+	// against the standard genre list.
+	Genre byte
+
+	// Track is the ID3v1.1 track number extension: the comment
+	// field's last byte, when its second-to-last byte is zero. It
+	// is 0 when the file carries a plain ID3v1 tag with no track
+	// number.
+	Track byte
+}
+
+// File is a parsed MP3 file's metadata: the frames of any ID3v2
+// tags Scan finds in the file, and any ID3v1 tag found in its last
+// 128 bytes.
+type File struct {
+	Frames Frames
+	ID3v1  *ID3v1
+}
+
+// ContainsID3v2 reports whether the file had an ID3v2 tag with at
+// least one frame; a tag holding only padding reports false.
+func (f *File) ContainsID3v2() bool {
+	return len(f.Frames) > 0
+}
+
+// ContainsID3v1 reports whether the file had a trailing ID3v1 tag.
+func (f *File) ContainsID3v1() bool {
+	return f.ID3v1 != nil
+}
+
+// Open reads the ID3v2 frames and, if present, the ID3v1 tag from
+// the file at path.
+func Open(path string) (*File, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	frames, err := Scan(fh)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{Frames: frames}
+
+	v1, err := readID3v1(fh)
+	if err != nil {
+		return nil, err
+	}
+	file.ID3v1 = v1
+
+	return file, nil
+}
+
+// readID3v1 looks for an ID3v1 tag in the last 128 bytes of fh. It
+// returns a nil ID3v1 and no error if the file is too short or does
+// not carry one.
+func readID3v1(fh *os.File) (*ID3v1, error) {
+	var tag [128]byte
+
+	if _, err := fh.Seek(-int64(len(tag)), io.SeekEnd); err != nil {
+		return nil, nil
+	}
+
+	if _, err := io.ReadFull(fh, tag[:]); err != nil {
+		return nil, err
+	}
+
+	if string(tag[:3]) != "TAG" {
+		return nil, nil
+	}
+
+	v1 := &ID3v1{
+		Title:  decodeID3v1String(tag[3:33]),
+		Artist: decodeID3v1String(tag[33:63]),
+		Album:  decodeID3v1String(tag[63:93]),
+		Year:   decodeID3v1String(tag[93:97]),
+		Genre:  tag[127],
+	}
+
+	comment := tag[97:127]
+	if comment[28] == 0 && comment[29] != 0 {
+		v1.Comment = decodeID3v1String(comment[:28])
+		v1.Track = comment[29]
+	} else {
+		v1.Comment = decodeID3v1String(comment)
+	}
+
+	return v1, nil
+}
+
+// decodeID3v1String decodes an ID3v1 fixed-width field, which is
+// ISO-8859-1 text padded with trailing zero bytes.
+func decodeID3v1String(b []byte) string {
+	b = bytes.TrimRight(b, "\x00")
+
+	s, err := charmap.ISO8859_1.NewDecoder().Bytes(b)
+	if err != nil {
+		return string(b)
+	}
+
+	return string(s)
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFile writes data to a new file under t.TempDir and
+// returns its path.
+func writeTestFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.mp3")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// TestOpenID3v2Only covers a file with a leading ID3v2 tag and no
+// trailing ID3v1 tag.
+func TestOpenID3v2Only(t *testing.T) {
+	frames := Frames{{ID: FramePRIV, Data: []byte("hello")}}
+
+	var buf bytes.Buffer
+	if err := frames.Encode(&buf, Version23); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	buf.WriteString("audio data")
+
+	path := writeTestFile(t, buf.Bytes())
+
+	f, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !f.ContainsID3v2() {
+		t.Error("ContainsID3v2() = false, want true")
+	}
+	if f.ContainsID3v1() {
+		t.Error("ContainsID3v1() = true, want false")
+	}
+	if len(f.Frames) != 1 || !bytes.Equal(f.Frames[0].Data, []byte("hello")) {
+		t.Errorf("Frames = %+v, want one PRIV frame with data %q", f.Frames, "hello")
+	}
+}
+
+// TestOpenID3v1 covers a file with a plain, pre-1.1 trailing ID3v1
+// tag (no track number).
+func TestOpenID3v1(t *testing.T) {
+	var tag [128]byte
+	copy(tag[:3], "TAG")
+	copy(tag[3:33], "Title")
+	copy(tag[33:63], "Artist")
+	copy(tag[63:93], "Album")
+	copy(tag[93:97], "2024")
+	copy(tag[97:127], "A comment")
+	tag[127] = 17 // Rock
+
+	path := writeTestFile(t, append([]byte("audio data"), tag[:]...))
+
+	f, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if f.ContainsID3v2() {
+		t.Error("ContainsID3v2() = true, want false")
+	}
+	if !f.ContainsID3v1() {
+		t.Fatal("ContainsID3v1() = false, want true")
+	}
+
+	want := &ID3v1{Title: "Title", Artist: "Artist", Album: "Album", Year: "2024", Comment: "A comment", Genre: 17}
+	if *f.ID3v1 != *want {
+		t.Errorf("ID3v1 = %+v, want %+v", *f.ID3v1, *want)
+	}
+}
+
+// TestOpenID3v1Track covers the ID3v1.1 track number extension: a
+// zero byte in the comment field's second-to-last position, followed
+// by a non-zero track number in its last.
+func TestOpenID3v1Track(t *testing.T) {
+	var tag [128]byte
+	copy(tag[:3], "TAG")
+	copy(tag[97:125], "short comment")
+	tag[125] = 0
+	tag[126] = 5
+
+	path := writeTestFile(t, tag[:])
+
+	f, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if f.ID3v1 == nil {
+		t.Fatal("ID3v1 = nil, want a tag")
+	}
+	if f.ID3v1.Comment != "short comment" {
+		t.Errorf("Comment = %q, want %q", f.ID3v1.Comment, "short comment")
+	}
+	if f.ID3v1.Track != 5 {
+		t.Errorf("Track = %d, want 5", f.ID3v1.Track)
+	}
+}
+
+// TestOpenNoTags covers a file with neither an ID3v2 nor an ID3v1
+// tag.
+func TestOpenNoTags(t *testing.T) {
+	path := writeTestFile(t, []byte("just audio data, no tags here"))
+
+	f, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if f.ContainsID3v2() {
+		t.Error("ContainsID3v2() = true, want false")
+	}
+	if f.ContainsID3v1() {
+		t.Error("ContainsID3v1() = true, want false")
+	}
+}
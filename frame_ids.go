@@ -0,0 +1,33 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+// These are the frame ids of the frames that have a typed decoder
+// on *Frame, as defined in §4 of id3v2.4.0-structure.txt.
+const (
+	// FrameCOMM is the "Comments" frame, decoded by Frame.Comment.
+	FrameCOMM FrameID = 0x434f4d4d
+	// FrameUSLT is the "Unsynchronised lyrics/text transcription"
+	// frame, decoded by Frame.UnsyncLyrics.
+	FrameUSLT FrameID = 0x55534c54
+	// FrameTXXX is the "User defined text information" frame,
+	// decoded by Frame.UserTextInfo.
+	FrameTXXX FrameID = 0x54585858
+	// FrameWXXX is the "User defined URL link" frame, decoded by
+	// Frame.UserURL.
+	FrameWXXX FrameID = 0x57585858
+	// FrameAPIC is the "Attached picture" frame, decoded by
+	// Frame.AttachedPicture.
+	FrameAPIC FrameID = 0x41504943
+	// FrameUFID is the "Unique file identifier" frame, decoded by
+	// Frame.UniqueFileID.
+	FrameUFID FrameID = 0x55464944
+	// FramePRIV is the "Private frame" frame, decoded by
+	// Frame.PrivateFrame.
+	FramePRIV FrameID = 0x50524956
+	// FrameGEOB is the "General encapsulated object" frame,
+	// decoded by Frame.GEOB.
+	FrameGEOB FrameID = 0x47454f42
+)
@@ -0,0 +1,392 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Comment is the decoded content of a COMM frame, described in §4.11
+// of id3v2.4.0-structure.txt.
+type Comment struct {
+	Language    [3]byte
+	Description string
+	Text        string
+}
+
+// Comment decodes the frame as a COMM comment frame.
+func (f *Frame) Comment() (*Comment, error) {
+	if f.ID != FrameCOMM {
+		return nil, fmt.Errorf("id3: frame %#08x is not a COMM frame", uint32(f.ID))
+	}
+	if len(f.Data) < 5 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+	var language [3]byte
+	copy(language[:], f.Data[1:4])
+
+	descriptionField, rest, err := splitTerminatedField(f.Data[4:], enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := decodeFrameText(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Comment{Language: language, Description: description, Text: text}, nil
+}
+
+// UnsyncLyrics is the decoded content of a USLT frame, described in
+// §4.9 of id3v2.4.0-structure.txt.
+type UnsyncLyrics struct {
+	Language    [3]byte
+	Description string
+	Text        string
+}
+
+// UnsyncLyrics decodes the frame as a USLT unsynchronised
+// lyrics/text transcription frame.
+func (f *Frame) UnsyncLyrics() (*UnsyncLyrics, error) {
+	if f.ID != FrameUSLT {
+		return nil, fmt.Errorf("id3: frame %#08x is not a USLT frame", uint32(f.ID))
+	}
+	if len(f.Data) < 5 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+	var language [3]byte
+	copy(language[:], f.Data[1:4])
+
+	descriptionField, rest, err := splitTerminatedField(f.Data[4:], enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := decodeFrameText(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnsyncLyrics{Language: language, Description: description, Text: text}, nil
+}
+
+// UserTextInfo is the decoded content of a TXXX frame, described in
+// §4.2.6 of id3v2.4.0-structure.txt.
+type UserTextInfo struct {
+	Description string
+	Value       string
+}
+
+// UserTextInfo decodes the frame as a TXXX user defined text
+// information frame.
+func (f *Frame) UserTextInfo() (*UserTextInfo, error) {
+	if f.ID != FrameTXXX {
+		return nil, fmt.Errorf("id3: frame %#08x is not a TXXX frame", uint32(f.ID))
+	}
+	if len(f.Data) < 1 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+
+	descriptionField, rest, err := splitTerminatedField(f.Data[1:], enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := decodeFrameText(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserTextInfo{Description: description, Value: value}, nil
+}
+
+// UserURL is the decoded content of a WXXX frame, described in §4.3.2
+// of id3v2.4.0-structure.txt.
+type UserURL struct {
+	Description string
+	URL         string
+}
+
+// UserURL decodes the frame as a WXXX user defined URL link frame.
+func (f *Frame) UserURL() (*UserURL, error) {
+	if f.ID != FrameWXXX {
+		return nil, fmt.Errorf("id3: frame %#08x is not a WXXX frame", uint32(f.ID))
+	}
+	if len(f.Data) < 1 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+
+	descriptionField, rest, err := splitTerminatedField(f.Data[1:], enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	// The URL itself is never encoded per the frame's encoding
+	// byte; it is always a plain ISO-8859-1 string.
+	url, err := decodeFrameText(rest, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserURL{Description: description, URL: url}, nil
+}
+
+// AttachedPicture is the decoded content of an APIC frame, described
+// in §4.14 of id3v2.4.0-structure.txt.
+type AttachedPicture struct {
+	MIME        string
+	PictureType byte
+	Description string
+	Data        []byte
+}
+
+// AttachedPicture decodes the frame as an APIC attached picture
+// frame.
+func (f *Frame) AttachedPicture() (*AttachedPicture, error) {
+	if f.ID != FrameAPIC {
+		return nil, fmt.Errorf("id3: frame %#08x is not an APIC frame", uint32(f.ID))
+	}
+	if len(f.Data) < 2 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+	data := f.Data[1:]
+
+	mimeField, rest, err := splitTerminatedField(data, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	mime, err := decodeFrameText(mimeField, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < 1 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	pictureType := rest[0]
+	rest = rest[1:]
+
+	descriptionField, rest, err := splitTerminatedField(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachedPicture{
+		MIME:        mime,
+		PictureType: pictureType,
+		Description: description,
+		Data:        append([]byte(nil), rest...),
+	}, nil
+}
+
+// UniqueFileID is the decoded content of a UFID frame, described in
+// §4.1 of id3v2.4.0-structure.txt.
+type UniqueFileID struct {
+	Owner string
+	ID    []byte
+}
+
+// UniqueFileID decodes the frame as a UFID unique file identifier
+// frame.
+func (f *Frame) UniqueFileID() (*UniqueFileID, error) {
+	if f.ID != FrameUFID {
+		return nil, fmt.Errorf("id3: frame %#08x is not a UFID frame", uint32(f.ID))
+	}
+
+	ownerField, rest, err := splitTerminatedField(f.Data, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := decodeFrameText(ownerField, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UniqueFileID{Owner: owner, ID: append([]byte(nil), rest...)}, nil
+}
+
+// PrivateFrame is the decoded content of a PRIV frame, described in
+// §4.27 of id3v2.4.0-structure.txt.
+type PrivateFrame struct {
+	Owner string
+	Data  []byte
+}
+
+// PrivateFrame decodes the frame as a PRIV private frame.
+func (f *Frame) PrivateFrame() (*PrivateFrame, error) {
+	if f.ID != FramePRIV {
+		return nil, fmt.Errorf("id3: frame %#08x is not a PRIV frame", uint32(f.ID))
+	}
+
+	ownerField, rest, err := splitTerminatedField(f.Data, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := decodeFrameText(ownerField, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateFrame{Owner: owner, Data: append([]byte(nil), rest...)}, nil
+}
+
+// GEOB is the decoded content of a GEOB frame, described in §4.16
+// of id3v2.4.0-structure.txt.
+type GEOB struct {
+	MIME        string
+	Filename    string
+	Description string
+	Data        []byte
+}
+
+// GEOB decodes the frame as a GEOB general encapsulated object
+// frame.
+func (f *Frame) GEOB() (*GEOB, error) {
+	if f.ID != FrameGEOB {
+		return nil, fmt.Errorf("id3: frame %#08x is not a GEOB frame", uint32(f.ID))
+	}
+	if len(f.Data) < 1 {
+		return nil, errors.New("id3: frame data is invalid")
+	}
+
+	enc := f.Data[0]
+	data := f.Data[1:]
+
+	mimeField, rest, err := splitTerminatedField(data, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	mime, err := decodeFrameText(mimeField, textEncodingISO88591)
+	if err != nil {
+		return nil, err
+	}
+
+	filenameField, rest, err := splitTerminatedField(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	filename, err := decodeFrameText(filenameField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptionField, rest, err := splitTerminatedField(rest, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := decodeFrameText(descriptionField, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GEOB{
+		MIME:        mime,
+		Filename:    filename,
+		Description: description,
+		Data:        append([]byte(nil), rest...),
+	}, nil
+}
+
+// textFrameEncoding returns the encoding.Encoding for one of the
+// textEncoding* bytes defined in §4 of id3v2.4.0-structure.txt.
+func textFrameEncoding(enc byte) (encoding.Encoding, error) {
+	switch enc {
+	case textEncodingISO88591:
+		return charmap.ISO8859_1, nil
+	case textEncodingUTF16:
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), nil
+	case textEncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	case textEncodingUTF8:
+		return encoding.Nop, nil
+	default:
+		return nil, errors.New("id3: frame uses unsupported encoding")
+	}
+}
+
+// splitTerminatedField splits data on the first string terminator
+// for enc: a single zero byte for ISO-8859-1 and UTF-8, or a pair
+// of zero bytes, aligned to a two-byte boundary, for UTF-16 and
+// UTF-16BE.
+func splitTerminatedField(data []byte, enc byte) (field, rest []byte, err error) {
+	if enc == textEncodingUTF16 || enc == textEncodingUTF16BE {
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return data[:i], data[i+2:], nil
+			}
+		}
+		return nil, nil, errors.New("id3: missing frame field terminator")
+	}
+
+	i := bytes.IndexByte(data, 0)
+	if i == -1 {
+		return nil, nil, errors.New("id3: missing frame field terminator")
+	}
+	return data[:i], data[i+1:], nil
+}
+
+// decodeFrameText decodes data as text in the given encoding.
+func decodeFrameText(data []byte, enc byte) (string, error) {
+	e, err := textFrameEncoding(enc)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := e.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("id3: frame has invalid text data: %w", err)
+	}
+
+	return string(decoded), nil
+}
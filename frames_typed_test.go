@@ -0,0 +1,206 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"testing"
+)
+
+// TestFrameComment covers decoding a COMM frame whose description
+// and text are ISO-8859-1.
+func TestFrameComment(t *testing.T) {
+	data := []byte{textEncodingISO88591, 'e', 'n', 'g'}
+	data = append(data, "short"...)
+	data = append(data, 0)
+	data = append(data, "long text"...)
+
+	f := &Frame{ID: FrameCOMM, Data: data}
+
+	c, err := f.Comment()
+	if err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if c.Language != ([3]byte{'e', 'n', 'g'}) {
+		t.Errorf("Language = %q, want %q", c.Language, "eng")
+	}
+	if c.Description != "short" {
+		t.Errorf("Description = %q, want %q", c.Description, "short")
+	}
+	if c.Text != "long text" {
+		t.Errorf("Text = %q, want %q", c.Text, "long text")
+	}
+}
+
+// TestFrameCommentWrongID covers calling Comment on a frame that
+// isn't a COMM frame.
+func TestFrameCommentWrongID(t *testing.T) {
+	f := &Frame{ID: FrameTXXX}
+	if _, err := f.Comment(); err == nil {
+		t.Fatal("Comment: got nil error, want an error for a non-COMM frame")
+	}
+}
+
+// TestFrameUserTextInfo covers decoding a TXXX frame.
+func TestFrameUserTextInfo(t *testing.T) {
+	f := &Frame{
+		ID:   FrameTXXX,
+		Data: append([]byte{textEncodingISO88591}, append([]byte("REPLAYGAIN_TRACK_GAIN"), append([]byte{0}, "-6.50 dB"...)...)...),
+	}
+
+	info, err := f.UserTextInfo()
+	if err != nil {
+		t.Fatalf("UserTextInfo: %v", err)
+	}
+	if info.Description != "REPLAYGAIN_TRACK_GAIN" {
+		t.Errorf("Description = %q, want %q", info.Description, "REPLAYGAIN_TRACK_GAIN")
+	}
+	if info.Value != "-6.50 dB" {
+		t.Errorf("Value = %q, want %q", info.Value, "-6.50 dB")
+	}
+}
+
+// TestFrameUserURL covers decoding a WXXX frame, whose URL field is
+// always ISO-8859-1 regardless of the frame's encoding byte.
+func TestFrameUserURL(t *testing.T) {
+	f := &Frame{
+		ID:   FrameWXXX,
+		Data: append([]byte{textEncodingUTF8}, append([]byte("homepage"), append([]byte{0}, "https://example.com"...)...)...),
+	}
+
+	u, err := f.UserURL()
+	if err != nil {
+		t.Fatalf("UserURL: %v", err)
+	}
+	if u.Description != "homepage" {
+		t.Errorf("Description = %q, want %q", u.Description, "homepage")
+	}
+	if u.URL != "https://example.com" {
+		t.Errorf("URL = %q, want %q", u.URL, "https://example.com")
+	}
+}
+
+// TestFrameAttachedPicture covers decoding an APIC frame.
+func TestFrameAttachedPicture(t *testing.T) {
+	data := append([]byte{textEncodingISO88591}, "image/jpeg"...)
+	data = append(data, 0, 3 /* front cover */)
+	data = append(data, "cover"...)
+	data = append(data, 0)
+	data = append(data, []byte{0xff, 0xd8, 0xff, 0xd9}...)
+
+	f := &Frame{ID: FrameAPIC, Data: data}
+
+	pic, err := f.AttachedPicture()
+	if err != nil {
+		t.Fatalf("AttachedPicture: %v", err)
+	}
+	if pic.MIME != "image/jpeg" {
+		t.Errorf("MIME = %q, want %q", pic.MIME, "image/jpeg")
+	}
+	if pic.PictureType != 3 {
+		t.Errorf("PictureType = %d, want 3", pic.PictureType)
+	}
+	if pic.Description != "cover" {
+		t.Errorf("Description = %q, want %q", pic.Description, "cover")
+	}
+	if string(pic.Data) != "\xff\xd8\xff\xd9" {
+		t.Errorf("Data = %x, want %x", pic.Data, "\xff\xd8\xff\xd9")
+	}
+}
+
+// TestFrameUniqueFileID covers decoding a UFID frame.
+func TestFrameUniqueFileID(t *testing.T) {
+	f := &Frame{ID: FrameUFID, Data: append([]byte("http://musicbrainz.org"), append([]byte{0}, []byte{1, 2, 3, 4}...)...)}
+
+	ufid, err := f.UniqueFileID()
+	if err != nil {
+		t.Fatalf("UniqueFileID: %v", err)
+	}
+	if ufid.Owner != "http://musicbrainz.org" {
+		t.Errorf("Owner = %q, want %q", ufid.Owner, "http://musicbrainz.org")
+	}
+	if string(ufid.ID) != "\x01\x02\x03\x04" {
+		t.Errorf("ID = %x, want %x", ufid.ID, "\x01\x02\x03\x04")
+	}
+}
+
+// TestFramePrivateFrame covers decoding a PRIV frame.
+func TestFramePrivateFrame(t *testing.T) {
+	f := &Frame{ID: FramePRIV, Data: append([]byte("com.example"), append([]byte{0}, []byte{0xde, 0xad}...)...)}
+
+	priv, err := f.PrivateFrame()
+	if err != nil {
+		t.Fatalf("PrivateFrame: %v", err)
+	}
+	if priv.Owner != "com.example" {
+		t.Errorf("Owner = %q, want %q", priv.Owner, "com.example")
+	}
+	if string(priv.Data) != "\xde\xad" {
+		t.Errorf("Data = %x, want %x", priv.Data, "\xde\xad")
+	}
+}
+
+// TestFrameGEOB covers decoding a GEOB frame.
+func TestFrameGEOB(t *testing.T) {
+	data := append([]byte{textEncodingISO88591}, "application/octet-stream"...)
+	data = append(data, 0)
+	data = append(data, "notes.txt"...)
+	data = append(data, 0)
+	data = append(data, "liner notes"...)
+	data = append(data, 0)
+	data = append(data, []byte{1, 2, 3}...)
+
+	f := &Frame{ID: FrameGEOB, Data: data}
+
+	geob, err := f.GEOB()
+	if err != nil {
+		t.Fatalf("GEOB: %v", err)
+	}
+	if geob.MIME != "application/octet-stream" {
+		t.Errorf("MIME = %q, want %q", geob.MIME, "application/octet-stream")
+	}
+	if geob.Filename != "notes.txt" {
+		t.Errorf("Filename = %q, want %q", geob.Filename, "notes.txt")
+	}
+	if geob.Description != "liner notes" {
+		t.Errorf("Description = %q, want %q", geob.Description, "liner notes")
+	}
+	if string(geob.Data) != "\x01\x02\x03" {
+		t.Errorf("Data = %x, want %x", geob.Data, "\x01\x02\x03")
+	}
+}
+
+// TestFrameUnsyncLyricsUTF16 covers decoding a USLT frame whose
+// description and text are UTF-16 with a byte order mark.
+func TestFrameUnsyncLyricsUTF16(t *testing.T) {
+	// "hi" and "yo" in UTF-16BE, each preceded by a BOM and
+	// followed by a two-byte terminator.
+	description := []byte{0xfe, 0xff, 0x00, 'h', 0x00, 'i', 0x00, 0x00}
+	text := []byte{0xfe, 0xff, 0x00, 'y', 0x00, 'o'}
+
+	data := append([]byte{textEncodingUTF16, 'e', 'n', 'g'}, description...)
+	data = append(data, text...)
+
+	f := &Frame{ID: FrameUSLT, Data: data}
+
+	lyrics, err := f.UnsyncLyrics()
+	if err != nil {
+		t.Fatalf("UnsyncLyrics: %v", err)
+	}
+	if lyrics.Description != "hi" {
+		t.Errorf("Description = %q, want %q", lyrics.Description, "hi")
+	}
+	if lyrics.Text != "yo" {
+		t.Errorf("Text = %q, want %q", lyrics.Text, "yo")
+	}
+}
+
+// TestFrameMissingTerminator covers a frame whose field is missing
+// its string terminator.
+func TestFrameMissingTerminator(t *testing.T) {
+	f := &Frame{ID: FrameTXXX, Data: []byte{textEncodingISO88591, 'n', 'o', 't', 'e', 'r', 'm'}}
+	if _, err := f.UserTextInfo(); err == nil {
+		t.Fatal("UserTextInfo: got nil error, want an error for a missing terminator")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"sync"
@@ -38,14 +39,25 @@ const (
 	Version23 Version = 0x03
 )
 
-const (
-	tagFlagUnsynchronisation = 1 << (7 - iota)
-	tagFlagExtendedHeader
-	tagFlagExperimental
-	tagFlagFooter
+// TagFlags are the tag-level ID3v2 header flags.
+type TagFlags byte
 
-	knownTagFlags = tagFlagUnsynchronisation | tagFlagExtendedHeader |
-		tagFlagExperimental | tagFlagFooter
+const (
+	// TagFlagUnsynchronisation indicates that unsynchronisation
+	// was applied to the whole tag.
+	TagFlagUnsynchronisation TagFlags = 1 << (7 - iota)
+	// TagFlagExtendedHeader indicates that an extended header,
+	// see ExtendedHeader, follows this header.
+	TagFlagExtendedHeader
+	// TagFlagExperimental indicates that the tag is in an
+	// experimental stage.
+	TagFlagExperimental
+	// TagFlagFooter indicates that a footer, identical to the
+	// header, follows the frames.
+	TagFlagFooter
+
+	knownTagFlags = TagFlagUnsynchronisation | TagFlagExtendedHeader |
+		TagFlagExperimental | TagFlagFooter
 )
 
 // FrameFlags are the frame-level ID3v2 flags.
@@ -165,7 +177,7 @@ func id3Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		return i + 3, nil, nil
 	}
 
-	if data[5]&^knownTagFlags != 0 {
+	if TagFlags(data[5])&^knownTagFlags != 0 {
 		// Skip tag blocks that contain unknown flags.
 		//
 		// Quoting from §3.1 of id3v2.4.0-structure.txt:
@@ -175,7 +187,7 @@ func id3Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		return i + 3, nil, nil
 	}
 
-	if data[5]&tagFlagFooter == tagFlagFooter {
+	if TagFlags(data[5])&TagFlagFooter == TagFlagFooter {
 		size += 10
 	}
 
@@ -223,10 +235,212 @@ var bufPool = &sync.Pool{
 	},
 }
 
-// Scan reads all valid ID3v2 tags from the reader and
-// returns all the frames in order. It returns an error
-// if the tags are invalid.
-func Scan(r io.Reader) (Frames, error) {
+// ErrExtendedHeaderCRC is the wrapped error returned when a tag's
+// extended header carries a CRC-32 that does not match its frame
+// data.
+var ErrExtendedHeaderCRC = errors.New("id3: extended header crc mismatch")
+
+// TagRestrictions are the tag restrictions declared in a v2.4.0
+// extended header, described in §3.2 of id3v2.4.0-structure.txt.
+// Each field holds the raw value of its restriction, whose meaning
+// is defined by the spec and is not otherwise interpreted here.
+type TagRestrictions struct {
+	TagSize       byte
+	TextEncoding  byte
+	TextFieldSize byte
+	ImageEncoding byte
+	ImageSize     byte
+}
+
+// ExtendedHeader is the optional block described in §3.2 of
+// id3v2.4.0-structure.txt and id3v2.3.0.txt, present when a tag's
+// TagFlagExtendedHeader flag is set.
+type ExtendedHeader struct {
+	// CRC32 is the CRC-32 checksum of the tag's frame data, or nil
+	// if the extended header does not carry one.
+	CRC32 *uint32
+
+	// SizeOfPadding is the number of padding bytes following the
+	// frames. It is only present in v2.3.0 tags.
+	SizeOfPadding uint32
+
+	// IsUpdate reports whether the tag is an update of a tag found
+	// earlier in the file. It is only meaningful in v2.4.0 tags.
+	IsUpdate bool
+
+	// Restrictions are the tag restrictions declared by the
+	// tagger, or nil if none were declared. It is only meaningful
+	// in v2.4.0 tags.
+	Restrictions *TagRestrictions
+}
+
+// Tag groups the header flags, optional extended header, and
+// frames of a single ID3v2 tag block.
+type Tag struct {
+	Version        Version
+	Flags          TagFlags
+	ExtendedHeader *ExtendedHeader
+	Frames         Frames
+}
+
+// parseExtendedHeader parses the extended header beginning at
+// data[0], returning the parsed header and the number of bytes it
+// occupies, including its own size field.
+func parseExtendedHeader(version Version, data []byte) (*ExtendedHeader, uint32, error) {
+	if len(data) < 4 {
+		return nil, 0, errors.New("id3: invalid extended header size")
+	}
+
+	var total uint32
+	switch version {
+	case Version24:
+		size := syncsafe(data[:4])
+		if size == syncsafeInvalid {
+			return nil, 0, errors.New("id3: invalid extended header size")
+		}
+		total = size + 4
+	case Version23:
+		total = binary.BigEndian.Uint32(data[:4]) + 4
+	default:
+		panic("unhandled version")
+	}
+
+	if uint32(len(data)) < total {
+		return nil, 0, errors.New("id3: invalid extended header size")
+	}
+
+	body := data[4:total]
+	eh := &ExtendedHeader{}
+
+	switch version {
+	case Version23:
+		if len(body) < 6 {
+			return nil, 0, errors.New("id3: invalid extended header size")
+		}
+
+		crcPresent := body[0]&0x80 != 0
+		eh.SizeOfPadding = binary.BigEndian.Uint32(body[2:6])
+
+		if crcPresent {
+			if len(body) < 10 {
+				return nil, 0, errors.New("id3: invalid extended header size")
+			}
+			crc := binary.BigEndian.Uint32(body[6:10])
+			eh.CRC32 = &crc
+		}
+	case Version24:
+		if len(body) < 2 || body[0] != 1 {
+			return nil, 0, errors.New("id3: invalid extended header")
+		}
+
+		extFlags := body[1]
+		body = body[2:]
+
+		if extFlags&0x40 != 0 {
+			if len(body) < 1 || body[0] != 0 {
+				return nil, 0, errors.New("id3: invalid extended header")
+			}
+			eh.IsUpdate = true
+			body = body[1:]
+		}
+
+		if extFlags&0x20 != 0 {
+			if len(body) < 6 || body[0] != 5 {
+				return nil, 0, errors.New("id3: invalid extended header")
+			}
+			crc := syncsafe5(body[1:6])
+			eh.CRC32 = &crc
+			body = body[6:]
+		}
+
+		if extFlags&0x10 != 0 {
+			if len(body) < 2 || body[0] != 1 {
+				return nil, 0, errors.New("id3: invalid extended header")
+			}
+			r := body[1]
+			eh.Restrictions = &TagRestrictions{
+				TagSize:       (r >> 6) & 0x3,
+				TextEncoding:  (r >> 5) & 0x1,
+				TextFieldSize: (r >> 3) & 0x3,
+				ImageEncoding: (r >> 2) & 0x1,
+				ImageSize:     r & 0x3,
+			}
+			body = body[2:]
+		}
+	}
+
+	return eh, total, nil
+}
+
+// syncsafe5 decodes a 5-byte syncsafe integer, as used to encode a
+// CRC-32 in a v2.4.0 extended header.
+func syncsafe5(data []byte) uint32 {
+	_ = data[4]
+
+	var v uint64
+	for _, b := range data[:5] {
+		v = v<<7 | uint64(b&0x7f)
+	}
+
+	return uint32(v)
+}
+
+// deunsynchronise returns a copy of data with every zero byte that
+// follows a 0xff byte removed, reversing the stuffing unsynchronise
+// applies on write.
+func deunsynchronise(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		v := data[i]
+		out = append(out, v)
+
+		if v == 0xff && i+1 < len(data) && data[i+1] == 0x00 {
+			i++
+		}
+	}
+
+	return out
+}
+
+// ScanOptions control which frames Scan, ScanFile, ScanTags, and
+// their *With variants decode. The zero value keeps every frame.
+type ScanOptions struct {
+	// Filter, if non-nil, is called for every frame encountered.
+	// Frames for which it returns false are skipped: their data
+	// is never copied or allocated, only its length is used to
+	// advance past it.
+	Filter func(FrameID) bool
+
+	// MaxFrameSize, if non-zero, bounds the size of any frame that
+	// will be decoded; larger frames are skipped like a filtered
+	// frame instead of being returned.
+	MaxFrameSize uint32
+
+	// StopAfterFirstTag stops scanning after the first ID3v2 tag
+	// block, ignoring any further tags concatenated after it.
+	StopAfterFirstTag bool
+}
+
+func (o ScanOptions) skip(id FrameID, size uint32) bool {
+	return o.Filter != nil && !o.Filter(id) ||
+		o.MaxFrameSize != 0 && size > o.MaxFrameSize
+}
+
+// ScanTags reads all valid ID3v2 tags from the reader and returns
+// them, with their frames, in order. It returns an error if the
+// tags are invalid.
+func ScanTags(r io.Reader) ([]*Tag, error) {
+	return scanTags(r, ScanOptions{})
+}
+
+// ScanTagsWith is ScanTags with the given options applied to every
+// frame encountered.
+func ScanTagsWith(r io.Reader, opts ScanOptions) ([]*Tag, error) {
+	return scanTags(r, opts)
+}
+
+func scanTags(r io.Reader, opts ScanOptions) ([]*Tag, error) {
 	buf := bufPool.Get().(*[]byte)
 	defer bufPool.Put(buf)
 
@@ -234,7 +448,7 @@ func Scan(r io.Reader) (Frames, error) {
 	s.Buffer(*buf, 20+1<<28)
 	s.Split(id3Split)
 
-	var frames Frames
+	var tags []*Tag
 
 	for s.Scan() {
 		data := s.Bytes()
@@ -253,9 +467,11 @@ func Scan(r io.Reader) (Frames, error) {
 			panic("id3: bufio.Scanner failed")
 		}
 
-		flags := header[5]
+		flags := TagFlags(header[5])
+
+		tag := &Tag{Version: version, Flags: flags}
 
-		if flags&tagFlagFooter == tagFlagFooter {
+		if flags&TagFlagFooter == TagFlagFooter {
 			footer := data[len(data)-10:]
 			data = data[:len(data)-10]
 
@@ -265,30 +481,28 @@ func Scan(r io.Reader) (Frames, error) {
 			}
 		}
 
-		if flags&tagFlagExtendedHeader == tagFlagExtendedHeader {
-			var size uint32
-			switch version {
-			case Version24:
-				size = syncsafe(data)
-				if size == syncsafeInvalid {
-					return nil, errors.New("id3: invalid extended header size")
-				}
-			case Version23:
-				size = binary.BigEndian.Uint32(data) + 4
-			default:
-				panic("unhandled version")
-			}
-
-			if len(data) < int(size) {
-				return nil, errors.New("id3: invalid extended header size")
+		if flags&TagFlagExtendedHeader == TagFlagExtendedHeader {
+			eh, n, err := parseExtendedHeader(version, data)
+			if err != nil {
+				return nil, err
 			}
 
-			extendedHeader := data[:size]
-			data = data[size:]
+			tag.ExtendedHeader = eh
+			data = data[n:]
+		}
 
-			_ = extendedHeader
+		if flags&TagFlagUnsynchronisation == TagFlagUnsynchronisation {
+			// The tag-level scheme unsynchronises the whole
+			// frames+padding blob as written, without updating
+			// any frame's declared size to account for the
+			// stuffed bytes it inserted. De-stuff it all up
+			// front, so that the declared sizes below are read
+			// against the same bytes they were computed from.
+			data = deunsynchronise(data)
 		}
 
+		frameData := data
+
 	frames:
 		for len(data) > 10 {
 			_ = data[9]
@@ -325,8 +539,12 @@ func Scan(r io.Reader) (Frames, error) {
 				return nil, errors.New("id3: frame size exceeds length of tag data")
 			}
 
-			if flags&tagFlagUnsynchronisation == tagFlagUnsynchronisation ||
-				version == Version24 && frame.Flags&FrameFlagV24Unsynchronisation != 0 {
+			if opts.skip(frame.ID, size) {
+				data = data[10+size:]
+				continue
+			}
+
+			if version == Version24 && frame.Flags&FrameFlagV24Unsynchronisation != 0 {
 				frame.Data = make([]byte, 0, size)
 
 				for i := uint32(0); i < size; i++ {
@@ -346,11 +564,11 @@ func Scan(r io.Reader) (Frames, error) {
 				frame.Data = append([]byte(nil), data[10:10+size]...)
 			}
 
-			frames = append(frames, frame)
+			tag.Frames = append(tag.Frames, frame)
 			data = data[10+size:]
 		}
 
-		if flags&tagFlagFooter == tagFlagFooter && len(data) != 0 {
+		if flags&TagFlagFooter == TagFlagFooter && len(data) != 0 {
 			return nil, errors.New("id3: padding with footer")
 		}
 
@@ -359,12 +577,56 @@ func Scan(r io.Reader) (Frames, error) {
 				return nil, errors.New("id3: invalid padding")
 			}
 		}
+
+		if tag.ExtendedHeader != nil && tag.ExtendedHeader.CRC32 != nil {
+			// The v2.4.0 CRC-32 covers the frames and the padding
+			// that follows them; the v2.3.0 CRC-32 covers the
+			// frames only.
+			crcData := frameData
+			if version == Version23 {
+				crcData = frameData[:len(frameData)-len(data)]
+			}
+
+			if got := crc32.ChecksumIEEE(crcData); got != *tag.ExtendedHeader.CRC32 {
+				return nil, fmt.Errorf("id3: extended header crc mismatch (got %#08x, want %#08x): %w",
+					got, *tag.ExtendedHeader.CRC32, ErrExtendedHeaderCRC)
+			}
+		}
+
+		tags = append(tags, tag)
+
+		if opts.StopAfterFirstTag {
+			break
+		}
 	}
 
 	if s.Err() != nil {
 		return nil, s.Err()
 	}
 
+	return tags, nil
+}
+
+// Scan reads all valid ID3v2 tags from the reader and
+// returns all the frames in order. It returns an error
+// if the tags are invalid.
+func Scan(r io.Reader) (Frames, error) {
+	return ScanWith(r, ScanOptions{})
+}
+
+// ScanWith is Scan with the given options applied to every frame
+// encountered.
+func ScanWith(r io.Reader, opts ScanOptions) (Frames, error) {
+	tags, err := scanTags(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames Frames
+	for _, tag := range tags {
+		frames = append(frames, tag.Frames...)
+	}
+
 	return frames, nil
 }
 
@@ -381,6 +643,18 @@ func ScanFile(path string) (Frames, error) {
 	return Scan(f)
 }
 
+// ScanFileWith is ScanFile with the given options applied to every
+// frame encountered.
+func ScanFileWith(path string, opts ScanOptions) (Frames, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ScanWith(f, opts)
+}
+
 // Frames is a slice of ID3v2 frames.
 type Frames []*Frame
 
@@ -402,6 +676,41 @@ type Frame struct {
 	Version Version
 	Flags   FrameFlags
 	Data    []byte
+
+	// reader, when non-nil, is the frame's still-unread body, as
+	// produced by Scanner.Next.
+	reader io.Reader
+}
+
+// Reader returns a reader over the frame's body. For frames
+// produced by Scan or ScanFile, it reads from the already-decoded
+// Data. For frames produced by a Scanner, it streams the body
+// directly from the underlying reader, without buffering it, and
+// must be read (or the Scanner closed) before the next call to
+// Scanner.Next.
+func (f *Frame) Reader() io.Reader {
+	if f.reader == nil {
+		return bytes.NewReader(f.Data)
+	}
+	return f.reader
+}
+
+// Bytes returns the frame's whole body, reading it into Data first
+// if it was produced by a Scanner and has not been read yet.
+func (f *Frame) Bytes() ([]byte, error) {
+	if f.reader == nil {
+		return f.Data, nil
+	}
+
+	data, err := io.ReadAll(f.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Data = data
+	f.reader = nil
+
+	return data, nil
 }
 
 func (f *Frame) String() string {
@@ -457,20 +766,6 @@ func (f *Frame) Text() (string, error) {
 		return "", errors.New("id3: frame uses unsupported encoding")
 	}
 
-	if f.ID == FrameCOMM {
-		data = f.Data[4:]
-		data = bytes.TrimSuffix(data, zeroByte)
-		enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
-		data, err := enc.NewDecoder().Bytes(data)
-		if err != nil {
-			return "", fmt.Errorf("id3: frame has invalid text data: %w", err)
-		}
-		data2 := StripNullUnicode(string(data))
-		data2 = StripUnicodeControlCharacters(data2)
-
-		return data2, nil
-	}
-
 	data, err := enc.NewDecoder().Bytes(data)
 	if err != nil {
 		return "", fmt.Errorf("id3: frame has invalid text data: %w", err)
@@ -0,0 +1,61 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// TestTagUnsynchronisationRoundTrip exercises a v2.3.0 tag encoded
+// with the tag-level Unsynchronisation option whose frame data
+// contains 0xff bytes, the case that unsynchronisation exists to
+// handle. Both Scan and Scanner must read back exactly what was
+// encoded.
+func TestTagUnsynchronisationRoundTrip(t *testing.T) {
+	want := []byte{0x00, 'h', 0xff, 'i', 0x00, 0xff, 0xff, 'j', 0xff}
+
+	frames := Frames{{ID: FramePRIV, Data: append([]byte(nil), want...)}}
+
+	var buf bytes.Buffer
+	if err := frames.Encode(&buf, Version23, EncodeOptions{Unsynchronisation: true}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	t.Run("Scan", func(t *testing.T) {
+		got, err := Scan(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d frames, want 1", len(got))
+		}
+		if !bytes.Equal(got[0].Data, want) {
+			t.Errorf("frame data = %x, want %x", got[0].Data, want)
+		}
+	})
+
+	t.Run("Scanner", func(t *testing.T) {
+		s := NewScanner(bytes.NewReader(buf.Bytes()))
+
+		frame, err := s.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+
+		got, err := frame.Bytes()
+		if err != nil {
+			t.Fatalf("Bytes: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("frame data = %x, want %x", got, want)
+		}
+
+		if _, err := s.Next(); err != io.EOF {
+			t.Errorf("Next: got %v, want io.EOF", err)
+		}
+	})
+}
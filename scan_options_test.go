@@ -0,0 +1,91 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestScanOptionsFilter covers skipping frames by ID: the filtered
+// frame's data must never show up in the result.
+func TestScanOptionsFilter(t *testing.T) {
+	frames := Frames{
+		{ID: FramePRIV, Data: []byte("keep me")},
+		{ID: FrameTXXX, Data: []byte{textEncodingISO88591, 0, 's', 'k', 'i', 'p'}},
+	}
+
+	var buf bytes.Buffer
+	if err := frames.Encode(&buf, Version23); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got, err := ScanWith(bytes.NewReader(buf.Bytes()), ScanOptions{
+		Filter: func(id FrameID) bool { return id != FrameTXXX },
+	})
+	if err != nil {
+		t.Fatalf("ScanWith: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+	if got[0].ID != FramePRIV {
+		t.Errorf("frame ID = %v, want FramePRIV", got[0].ID)
+	}
+}
+
+// TestScanOptionsMaxFrameSize covers skipping frames larger than
+// MaxFrameSize.
+func TestScanOptionsMaxFrameSize(t *testing.T) {
+	frames := Frames{
+		{ID: FramePRIV, Data: []byte("short")},
+		{ID: FrameGEOB, Data: bytes.Repeat([]byte{'x'}, 100)},
+	}
+
+	var buf bytes.Buffer
+	if err := frames.Encode(&buf, Version23); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got, err := ScanWith(bytes.NewReader(buf.Bytes()), ScanOptions{MaxFrameSize: 10})
+	if err != nil {
+		t.Fatalf("ScanWith: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+	if got[0].ID != FramePRIV {
+		t.Errorf("frame ID = %v, want FramePRIV", got[0].ID)
+	}
+}
+
+// TestScanOptionsStopAfterFirstTag covers ignoring further tags
+// concatenated after the first one.
+func TestScanOptionsStopAfterFirstTag(t *testing.T) {
+	first := Frames{{ID: FramePRIV, Data: []byte("first")}}
+	second := Frames{{ID: FramePRIV, Data: []byte("second")}}
+
+	var buf bytes.Buffer
+	if err := first.Encode(&buf, Version23); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := second.Encode(&buf, Version23); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tags, err := ScanTagsWith(bytes.NewReader(buf.Bytes()), ScanOptions{StopAfterFirstTag: true})
+	if err != nil {
+		t.Fatalf("ScanTagsWith: %v", err)
+	}
+
+	if len(tags) != 1 {
+		t.Fatalf("got %d tags, want 1", len(tags))
+	}
+	if len(tags[0].Frames) != 1 || !bytes.Equal(tags[0].Frames[0].Data, []byte("first")) {
+		t.Errorf("tag frames = %+v, want one PRIV frame with data %q", tags[0].Frames, "first")
+	}
+}
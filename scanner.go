@@ -0,0 +1,415 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Scanner streams the frames of the first ID3v2 tag found in a
+// reader, without buffering the whole tag as Scan does. Unlike
+// Scan, it does not look for further tags concatenated after the
+// first one.
+//
+// Scanner's zero value is not usable; construct one with
+// NewScanner.
+type Scanner struct {
+	r   *bufio.Reader
+	err error
+
+	version        Version
+	flags          TagFlags
+	extendedHeader *ExtendedHeader
+
+	started bool
+
+	// raw counts the bytes pulled from r since the tag header was
+	// found, regardless of whether they were read directly or
+	// through body's unsynchronisation transform.
+	raw *countingReader
+
+	// body is where frame headers and bodies are read from: raw
+	// itself, or raw wrapped in an unsyncReader when the tag is
+	// flagged as unsynchronised. It is shared across every frame in
+	// the tag, so that a lookahead byte buffered at a frame
+	// boundary carries over correctly to the next frame.
+	body io.Reader
+
+	// tagBudget is the number of raw frame-and-padding bytes the
+	// header declared, and tagRawBase is raw.n at the point that
+	// region begins; remainingRaw derives the bytes left to read
+	// from the two, since unsynchronisation makes the raw bytes a
+	// frame consumes unknowable in advance.
+	tagBudget  int64
+	tagRawBase int64
+
+	// frame is the still-to-be-read body of the most recently
+	// returned frame, bounded to its declared (post-destuffing)
+	// size. Its N shrinks as the caller reads from the Frame's
+	// Reader; draining it is always safe, whether or not the
+	// caller read any of it.
+	frame *io.LimitedReader
+
+	footerRead bool
+}
+
+// countingReader wraps r, recording the number of bytes read from
+// it, so that reads made through a transform built on top of it can
+// still be charged against a raw byte budget.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// NewScanner returns a Scanner that reads frames from r.
+func NewScanner(r io.Reader) *Scanner {
+	br := bufio.NewReaderSize(r, 32<<10)
+	return &Scanner{r: br, raw: &countingReader{r: br}}
+}
+
+// Version returns the tag's version. It is only valid once Next
+// has returned a frame, or a nil frame and a non-EOF error.
+func (s *Scanner) Version() Version {
+	return s.version
+}
+
+// TagFlags returns the tag's header flags. It is only valid once
+// Next has returned a frame, or a nil frame and a non-EOF error.
+func (s *Scanner) TagFlags() TagFlags {
+	return s.flags
+}
+
+// ExtendedHeader returns the tag's extended header, or nil if it
+// did not have one. It is only valid once Next has returned a
+// frame, or a nil frame and a non-EOF error.
+func (s *Scanner) ExtendedHeader() *ExtendedHeader {
+	return s.extendedHeader
+}
+
+// Next returns the next frame in the tag. Its Data is left empty;
+// callers that want it read into memory should call Frame.Bytes,
+// and callers that want to stream it (to a large file, say)
+// should call Frame.Reader. Next returns io.EOF once every frame
+// has been returned.
+func (s *Scanner) Next() (*Frame, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	frame, err := s.next()
+	if err != nil {
+		s.err = err
+	}
+
+	return frame, err
+}
+
+// remainingRaw returns the number of raw, still-unread bytes left
+// in the tag's frame-and-padding region.
+func (s *Scanner) remainingRaw() int64 {
+	return s.tagBudget - (s.raw.n - s.tagRawBase)
+}
+
+func (s *Scanner) next() (*Frame, error) {
+	if err := s.drainFrame(); err != nil {
+		return nil, err
+	}
+
+	if !s.started {
+		s.started = true
+		if err := s.readTagHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.remainingRaw() < 10 {
+		return nil, s.finish()
+	}
+
+	var header [10]byte
+	if _, err := io.ReadFull(s.body, header[:]); err != nil {
+		return nil, err
+	}
+
+	id := frameID(header[:4])
+	switch id {
+	case 0:
+		// We've hit padding; the 10 bytes just read were part of
+		// it, and the rest of the tag is padding too.
+		return nil, s.finish()
+	case invalidFrameID:
+		return nil, errors.New("id3: invalid frame id")
+	}
+
+	frame := &Frame{
+		ID:      id,
+		Version: s.version,
+		Flags:   FrameFlags(binary.BigEndian.Uint16(header[8:10])),
+	}
+
+	var size uint32
+	switch s.version {
+	case Version24:
+		size = syncsafe(header[4:8])
+		if size == syncsafeInvalid {
+			return nil, errors.New("id3: invalid frame size")
+		}
+	case Version23:
+		size = binary.BigEndian.Uint32(header[4:8])
+	}
+
+	if int64(size) > s.remainingRaw() {
+		return nil, errors.New("id3: frame size exceeds length of tag data")
+	}
+
+	s.frame = &io.LimitedReader{R: s.body, N: int64(size)}
+
+	var body io.Reader = s.frame
+	if s.version == Version24 && frame.Flags&FrameFlagV24Unsynchronisation != 0 {
+		body = newUnsyncReader(body)
+	}
+	frame.reader = body
+
+	return frame, nil
+}
+
+// readTagHeader finds and parses the tag header and, if present,
+// its extended header, advancing past both. It also sets up body,
+// the reader frames are read from for the rest of the tag.
+//
+// A match on the literal bytes "ID3" that isn't followed by a
+// structurally valid header is treated as a coincidental occurrence
+// in audio data, consistent with §3.1 and with the tolerance Scan
+// already provides via id3Split: the search resumes from the next
+// byte instead of failing.
+func (s *Scanner) readTagHeader() error {
+	var window [3]byte
+	if _, err := io.ReadFull(s.raw, window[:]); err != nil {
+		return err
+	}
+
+	// pending holds bytes already read, and counted in raw.n, that
+	// the search must look at again before reading any further.
+	var pending []byte
+
+	for {
+		for window != ([3]byte{'I', 'D', '3'}) {
+			var b byte
+			if len(pending) > 0 {
+				b, pending = pending[0], pending[1:]
+			} else if c, err := s.r.ReadByte(); err != nil {
+				return err
+			} else {
+				b = c
+				s.raw.n++
+			}
+			window[0], window[1], window[2] = window[1], window[2], b
+		}
+
+		var rest [7]byte
+		n := copy(rest[:], pending)
+		pending = pending[n:]
+		if _, err := io.ReadFull(s.raw, rest[n:]); err != nil {
+			return err
+		}
+
+		version := Version(rest[0])
+		validVersion := version == Version23 || version == Version24
+		flags := TagFlags(rest[2])
+		size := syncsafe(rest[3:7])
+
+		if !validVersion || rest[1] == 0xff || flags&^knownTagFlags != 0 || size == syncsafeInvalid {
+			// Not a real tag header; resume the search without
+			// discarding the bytes already read, since a real
+			// "ID3" may start anywhere inside them. Neither "D" nor
+			// "3" can begin one, so the search restarts at rest[0].
+			pending = append([]byte(nil), rest[:]...)
+			window = [3]byte{}
+			continue
+		}
+
+		s.version = version
+		s.flags = flags
+		s.tagBudget = int64(size)
+		s.tagRawBase = s.raw.n
+
+		if flags&TagFlagExtendedHeader != 0 {
+			var sizeField [4]byte
+			if _, err := io.ReadFull(s.raw, sizeField[:]); err != nil {
+				return err
+			}
+
+			var total uint32
+			switch version {
+			case Version24:
+				n := syncsafe(sizeField[:])
+				if n == syncsafeInvalid {
+					return errors.New("id3: invalid extended header size")
+				}
+				total = n + 4
+			case Version23:
+				total = binary.BigEndian.Uint32(sizeField[:]) + 4
+			}
+
+			if int64(total) > s.remainingRaw() {
+				return errors.New("id3: invalid extended header size")
+			}
+
+			body := make([]byte, total)
+			copy(body, sizeField[:])
+			if _, err := io.ReadFull(s.raw, body[4:]); err != nil {
+				return err
+			}
+
+			eh, _, err := parseExtendedHeader(version, body)
+			if err != nil {
+				return err
+			}
+
+			s.extendedHeader = eh
+		}
+
+		// The tag-level unsynchronisation scheme stuffs the whole
+		// frames-and-padding region as written, without updating
+		// any frame's declared size to account for the bytes it
+		// inserted. Sharing a single destuffing reader across every
+		// frame in the tag, instead of wrapping each frame's raw
+		// bytes on their own, keeps the declared (pre-stuffing)
+		// sizes aligned with the bytes they were computed from.
+		//
+		// The destuffing reader's one-byte lookahead must not be
+		// allowed to run past the declared frame-and-padding
+		// region: a 0xff as the very last byte of the tag would
+		// otherwise pull in whatever follows it in the underlying
+		// reader (more concatenated tags, trailing audio, ...) and
+		// risk stranding that byte in the lookahead buffer, never
+		// delivered to any caller. Bounding the source with an
+		// io.LimitedReader makes the lookahead see EOF at the tag
+		// boundary instead.
+		if flags&TagFlagUnsynchronisation != 0 {
+			s.body = newUnsyncReader(&io.LimitedReader{R: s.raw, N: s.remainingRaw()})
+		} else {
+			s.body = s.raw
+		}
+
+		return nil
+	}
+}
+
+// drainFrame discards any unread bytes of the most recently
+// returned frame's body.
+func (s *Scanner) drainFrame() error {
+	if s.frame == nil || s.frame.N == 0 {
+		return nil
+	}
+
+	_, err := io.Copy(io.Discard, s.frame)
+	return err
+}
+
+// finish discards the tag's remaining padding and, if present,
+// reads and validates its footer. It always returns io.EOF once
+// it completes successfully, to signal that every frame has been
+// returned.
+func (s *Scanner) finish() error {
+	if remaining := s.remainingRaw(); remaining > 0 {
+		// Padding is always literal zero bytes, never themselves
+		// unsynchronised, so it's always safe to drain it straight
+		// off the underlying reader rather than through body.
+		if _, err := io.CopyN(io.Discard, s.r, remaining); err != nil {
+			return err
+		}
+		s.raw.n += remaining
+	}
+
+	if s.flags&TagFlagFooter != 0 && !s.footerRead {
+		var footer [10]byte
+		if _, err := io.ReadFull(s.r, footer[:]); err != nil {
+			return err
+		}
+		if string(footer[:3]) != "3DI" || footer[3] != byte(s.version) {
+			return errors.New("id3: invalid footer")
+		}
+		s.footerRead = true
+	}
+
+	return io.EOF
+}
+
+// Close discards any unread frame body and the tag's remaining
+// padding and footer, so that the Scanner has consumed exactly the
+// tag. The underlying reader is read through a buffer, so it may
+// already have been read past the end of the tag. Close is useful
+// to stop scanning early, e.g. after reading only the first few
+// frames.
+func (s *Scanner) Close() error {
+	if err := s.drainFrame(); err != nil {
+		return err
+	}
+
+	if err := s.finish(); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// unsyncReader removes the zero byte that follows every 0xff byte
+// read from r, reversing the unsynchronisation scheme applied on
+// write.
+type unsyncReader struct {
+	r    io.Reader
+	peek byte
+	has  bool
+}
+
+func newUnsyncReader(r io.Reader) io.Reader {
+	return &unsyncReader{r: r}
+}
+
+func (u *unsyncReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		var b byte
+		if u.has {
+			b = u.peek
+			u.has = false
+		} else {
+			var buf [1]byte
+			if _, err := u.r.Read(buf[:]); err != nil {
+				if n > 0 {
+					return n, nil
+				}
+				return 0, err
+			}
+			b = buf[0]
+		}
+
+		if b == 0xff {
+			var next [1]byte
+			if _, err := io.ReadFull(u.r, next[:]); err == nil {
+				if next[0] != 0 {
+					u.peek, u.has = next[0], true
+				}
+			} else if err != io.EOF && err != io.ErrUnexpectedEOF {
+				return n, err
+			}
+		}
+
+		p[n] = b
+		n++
+	}
+
+	return n, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 Tom Thorogood. All rights reserved.
+// Use of this source code is governed by a Modified
+// BSD License that can be found in the LICENSE file.
+
+package id3v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestScannerUnsynchronisationBoundaryLookahead covers a
+// tag-level-unsynchronised tag whose declared frame-and-padding
+// region ends in a raw 0xff with no stuffed zero byte after it
+// within the tag (as could come from a non-conformant encoder, or a
+// frame's last logical byte landing exactly on the tag boundary).
+// The shared unsyncReader's one-byte lookahead must stop at that
+// boundary instead of consuming a byte of whatever follows the tag.
+func TestScannerUnsynchronisationBoundaryLookahead(t *testing.T) {
+	frameData := []byte{'o', 'w', 'n', 0x00, 'h', 0xff}
+	frameHeader := []byte{'P', 'R', 'I', 'V', 0, 0, 0, byte(len(frameData)), 0, 0}
+	tagBody := append(append([]byte(nil), frameHeader...), frameData...)
+
+	var tagHeader [10]byte
+	copy(tagHeader[:3], "ID3")
+	tagHeader[3] = byte(Version23)
+	tagHeader[5] = byte(TagFlagUnsynchronisation)
+	putSyncsafe(tagHeader[6:10], uint32(len(tagBody)))
+
+	var buf bytes.Buffer
+	buf.Write(tagHeader[:])
+	buf.Write(tagBody)
+	buf.Write([]byte("trailingdata"))
+
+	s := NewScanner(bytes.NewReader(buf.Bytes()))
+
+	frame, err := s.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if got, err := frame.Bytes(); err != nil || !bytes.Equal(got, frameData) {
+		t.Fatalf("frame data = %x, %v; want %x, nil", got, err, frameData)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if consumed := s.raw.n - s.tagRawBase; consumed != s.tagBudget {
+		t.Fatalf("consumed %d raw bytes of the tag, want exactly the declared %d", consumed, s.tagBudget)
+	}
+}
@@ -0,0 +1,71 @@
+package id3v2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileNewFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "new.mp3")
+
+	frames := Frames{{ID: FrameTXXX, Data: append([]byte{textEncodingUTF8}, []byte("k\x00v")...)}}
+	if err := WriteFile(path, frames, Version24); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := ScanFile(path)
+	if err != nil {
+		t.Fatalf("ScanFile: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+}
+
+func TestWriteFilePreservesAudioAndReplacesTag(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "existing.mp3")
+
+	audio := bytes.Repeat([]byte{0x55, 0xAA, 0x00, 0xFF}, 64)
+
+	oldFrames := Frames{{ID: FrameTXXX, Data: append([]byte{textEncodingUTF8}, []byte("old\x00value")...)}}
+	var tag bytes.Buffer
+	if err := oldFrames.Encode(&tag, Version24); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	if err := os.WriteFile(path, append(append([]byte(nil), tag.Bytes()...), audio...), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	newFrames := Frames{{ID: FrameTXXX, Data: append([]byte{textEncodingUTF8}, []byte("new\x00value")...)}}
+	if err := WriteFile(path, newFrames, Version24); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+	if !bytes.HasSuffix(raw, audio) {
+		t.Fatalf("audio payload was not preserved")
+	}
+
+	got, err := ScanFile(path)
+	if err != nil {
+		t.Fatalf("ScanFile: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d frames, want 1", len(got))
+	}
+	info, err := got[0].UserTextInfo()
+	if err != nil {
+		t.Fatalf("UserTextInfo: %v", err)
+	}
+	if info.Value != "value" || info.Description != "new" {
+		t.Fatalf("got %+v, want new/value", info)
+	}
+}